@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mehdieidi/dexxt/store"
+)
+
+// HandlerFunc handles a single update routed to it by a Bot.
+type HandlerFunc func(c *Context) error
+
+// MiddlewareFunc wraps a HandlerFunc to add cross-cutting behavior such as
+// logging, rate-limiting, auth, or panic recovery.
+type MiddlewareFunc func(HandlerFunc) HandlerFunc
+
+// Bot routes updates, from either the webhook Handler or a long-polling
+// Start loop, to a command handler or the default text handler, through a
+// shared middleware chain.
+type Bot struct {
+	token  string
+	client *Client
+
+	stop chan struct{}
+
+	commands    map[string]HandlerFunc
+	middleware  []MiddlewareFunc
+	textHandler HandlerFunc
+
+	transcoder   Transcoder
+	recognizer   SpeechRecognizer
+	maxFileSize  int64
+	mediaTimeout time.Duration
+
+	chatSlotsMu sync.Mutex
+	chatSlots   map[int]chan struct{}
+
+	store store.Store
+}
+
+// NewBot returns a Bot configured with the given Telegram bot token. The
+// default text handler runs the Finglish-to-Farsi conversion; register
+// commands with Handle and register middleware with Use.
+func NewBot(token string) *Bot {
+	return &Bot{
+		token:       token,
+		client:      NewClient(token),
+		commands:    make(map[string]HandlerFunc),
+		textHandler: defaultTextHandler,
+	}
+}
+
+// Handle registers a HandlerFunc for a command such as "/start".
+func (b *Bot) Handle(command string, h HandlerFunc) {
+	b.commands[command] = h
+}
+
+// Use appends middleware to the chain that wraps every handler, command or
+// default text, in registration order.
+func (b *Bot) Use(mw ...MiddlewareFunc) {
+	b.middleware = append(b.middleware, mw...)
+}
+
+// WithMedia enables voice/audio handling: incoming voice notes and audio
+// messages are downloaded, passed through transcoder, then recognizer, and
+// the resulting text is fed into the same pipeline as a typed message.
+// maxFileSize caps downloaded file size in bytes and timeout bounds the
+// getFile lookup and download together; transcoding and recognition are
+// not bounded by it. Zero values fall back to defaultMaxFileSize and
+// defaultMediaTimeout.
+func (b *Bot) WithMedia(transcoder Transcoder, recognizer SpeechRecognizer, maxFileSize int64, timeout time.Duration) {
+	b.transcoder = transcoder
+	b.recognizer = recognizer
+	b.maxFileSize = maxFileSize
+	b.mediaTimeout = timeout
+}
+
+// WithStore enables persistence of per-chat preferences, conversion
+// history and glossary overrides. Without a store, /settings, /history
+// and /glossary report that persistence isn't configured, and the bot
+// always runs with the package-level onlineMode default.
+func (b *Bot) WithStore(s store.Store) {
+	b.store = s
+}
+
+// Start begins pulling updates from poller and routing them through
+// dispatch until Stop is called. Start blocks until the poller stops.
+func (b *Bot) Start(poller Poller) {
+	dest := make(chan update)
+	b.stop = make(chan struct{})
+
+	go poller.Poll(b, dest, b.stop)
+
+	for u := range dest {
+		b.dispatch(u)
+	}
+}
+
+// Stop halts the poller started by Start.
+func (b *Bot) Stop() {
+	if b.stop != nil {
+		close(b.stop)
+	}
+}
+
+// dispatch routes a single update to its handler, wrapped in the
+// registered middleware chain. Logging is the middleware chain's job, not
+// dispatch's; see LoggingMiddleware.
+func (b *Bot) dispatch(u update) {
+	c := &Context{bot: b, update: u}
+	h := b.handlerFor(u)
+
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		h = b.middleware[i](h)
+	}
+
+	h(c)
+}
+
+// handlerFor picks the media handler for a voice/audio/document message,
+// then the command handler matching the message's first word, falling
+// back to the default text handler.
+func (b *Bot) handlerFor(u update) HandlerFunc {
+	switch {
+	case u.Message.Voice.FileId != "":
+		return b.mediaHandler(u.Message.Voice.FileId)
+	case u.Message.Audio.FileId != "":
+		return b.mediaHandler(u.Message.Audio.FileId)
+	case u.Message.Document.FileId != "" && strings.HasSuffix(strings.ToLower(u.Message.Document.FileName), ".txt"):
+		return b.documentHandler(u.Message.Document.FileId)
+	}
+
+	fields := strings.Fields(u.Message.Text)
+	if len(fields) > 0 {
+		if h, ok := b.commands[fields[0]]; ok {
+			return h
+		}
+	}
+	return b.textHandler
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Parse modes accepted by Telegram's sendMessage/sendDocument/sendVoice.
+const (
+	ParseModeMarkdownV2 = "MarkdownV2"
+	ParseModeHTML       = "HTML"
+)
+
+// telegramMaxMessageLength is Telegram's limit on a sendMessage text; past
+// this, Context.Send uploads the text as a document instead.
+const telegramMaxMessageLength = 4096
+
+// InlineKeyboardButton is a single button in an InlineKeyboardMarkup.
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// InlineKeyboardMarkup is a grid of inline keyboard buttons attached to a
+// message.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// sendParams collects the options a SendOption can set, shared across the
+// form-encoded and multipart send methods.
+type sendParams struct {
+	parseMode             string
+	replyToMessageID      int
+	disableWebPagePreview bool
+	replyMarkup           *InlineKeyboardMarkup
+}
+
+// SendOption configures an optional parameter of a Client send method.
+type SendOption func(*sendParams)
+
+// ParseMode sets the parse mode (ParseModeMarkdownV2 or ParseModeHTML) used
+// to format the message text.
+func ParseMode(mode string) SendOption {
+	return func(p *sendParams) { p.parseMode = mode }
+}
+
+// ReplyToMessageID makes the message a reply to an earlier message.
+func ReplyToMessageID(messageID int) SendOption {
+	return func(p *sendParams) { p.replyToMessageID = messageID }
+}
+
+// DisableWebPagePreview turns off link previews in the sent message.
+func DisableWebPagePreview() SendOption {
+	return func(p *sendParams) { p.disableWebPagePreview = true }
+}
+
+// WithReplyMarkup attaches an inline keyboard to the sent message.
+func WithReplyMarkup(markup InlineKeyboardMarkup) SendOption {
+	return func(p *sendParams) { p.replyMarkup = &markup }
+}
+
+func buildSendParams(opts []SendOption) *sendParams {
+	p := &sendParams{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// addToValues adds the set options as form fields.
+func (p *sendParams) addToValues(values url.Values) error {
+	if p.parseMode != "" {
+		values.Set("parse_mode", p.parseMode)
+	}
+	if p.replyToMessageID != 0 {
+		values.Set("reply_to_message_id", strconv.Itoa(p.replyToMessageID))
+	}
+	if p.disableWebPagePreview {
+		values.Set("disable_web_page_preview", "true")
+	}
+	if p.replyMarkup != nil {
+		b, err := json.Marshal(p.replyMarkup)
+		if err != nil {
+			return fmt.Errorf("err marshaling reply markup: %w", err)
+		}
+		values.Set("reply_markup", string(b))
+	}
+	return nil
+}
+
+// writeFields writes the set options as multipart form fields, except
+// disableWebPagePreview, which it does not write.
+func (p *sendParams) writeFields(mw *multipart.Writer) error {
+	if p.parseMode != "" {
+		if err := mw.WriteField("parse_mode", p.parseMode); err != nil {
+			return err
+		}
+	}
+	if p.replyToMessageID != 0 {
+		if err := mw.WriteField("reply_to_message_id", strconv.Itoa(p.replyToMessageID)); err != nil {
+			return err
+		}
+	}
+	if p.replyMarkup != nil {
+		b, err := json.Marshal(p.replyMarkup)
+		if err != nil {
+			return fmt.Errorf("err marshaling reply markup: %w", err)
+		}
+		if err := mw.WriteField("reply_markup", string(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Client talks to the Telegram Bot API for a single bot token.
+type Client struct {
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a Client for the given Telegram bot token.
+func NewClient(token string) *Client {
+	return &Client{token: token, httpClient: http.DefaultClient}
+}
+
+func (cl *Client) apiURL(method string) string {
+	return TELEGRAM_API_BASE_URL + cl.token + "/" + method
+}
+
+// SendMessage sends a text message to chatID.
+func (cl *Client) SendMessage(chatID int, text string, opts ...SendOption) (string, error) {
+	p := buildSendParams(opts)
+
+	values := url.Values{
+		"chat_id": {strconv.Itoa(chatID)},
+		"text":    {text},
+	}
+	if err := p.addToValues(values); err != nil {
+		return "", err
+	}
+
+	return cl.postForm("sendMessage", values)
+}
+
+// SendDocument uploads content as a document named filename to chatID.
+func (cl *Client) SendDocument(chatID int, filename string, content io.Reader, opts ...SendOption) (string, error) {
+	return cl.sendFile("sendDocument", "document", chatID, filename, content, opts...)
+}
+
+// SendVoice uploads content as a voice note named filename to chatID.
+func (cl *Client) SendVoice(chatID int, filename string, content io.Reader, opts ...SendOption) (string, error) {
+	return cl.sendFile("sendVoice", "voice", chatID, filename, content, opts...)
+}
+
+// AnswerCallbackQuery acknowledges an inline keyboard button press.
+func (cl *Client) AnswerCallbackQuery(callbackQueryID string, opts ...SendOption) (string, error) {
+	p := buildSendParams(opts)
+
+	values := url.Values{"callback_query_id": {callbackQueryID}}
+	if err := p.addToValues(values); err != nil {
+		return "", err
+	}
+
+	return cl.postForm("answerCallbackQuery", values)
+}
+
+// sendFile builds a multipart/form-data request uploading content under
+// field, the way Telegram's file-accepting endpoints require.
+func (cl *Client) sendFile(method, field string, chatID int, filename string, content io.Reader, opts ...SendOption) (string, error) {
+	p := buildSendParams(opts)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	if err := mw.WriteField("chat_id", strconv.Itoa(chatID)); err != nil {
+		return "", fmt.Errorf("err writing chat_id field: %w", err)
+	}
+
+	part, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		return "", fmt.Errorf("err creating form file: %w", err)
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return "", fmt.Errorf("err copying file into request: %w", err)
+	}
+
+	if err := p.writeFields(mw); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("err closing multipart writer: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, cl.apiURL(method), &body)
+	if err != nil {
+		return "", fmt.Errorf("err creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return cl.do(req)
+}
+
+func (cl *Client) postForm(method string, values url.Values) (string, error) {
+	resp, err := cl.httpClient.PostForm(cl.apiURL(method), values)
+	if err != nil {
+		return "", fmt.Errorf("err calling %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	return readBody(resp)
+}
+
+func (cl *Client) do(req *http.Request) (string, error) {
+	resp, err := cl.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("err sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return readBody(resp)
+}
+
+func readBody(resp *http.Response) (string, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("err reading response body: %w", err)
+	}
+	return string(body), nil
+}
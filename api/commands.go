@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"log"
+	"os"
+
+	"github.com/mehdieidi/dexxt/store"
+)
+
+// defaultBot is the Bot used by the webhook Handler. A standalone daemon
+// can instead construct its own Bot with NewBot and call Start with a
+// Poller.
+var defaultBot = newDefaultBot()
+
+// WHISPER_ENDPOINT_ENV, if set, points at a Whisper HTTP API and enables
+// voice/audio message transcription.
+const WHISPER_ENDPOINT_ENV = "WHISPER_ENDPOINT"
+
+// STORE_DIR_ENV, if set, points at a directory for a BadgerDB-backed
+// store and enables /settings, /history and /glossary. Without it, those
+// commands report that persistence isn't configured.
+const STORE_DIR_ENV = "DEXXT_STORE_DIR"
+
+// newDefaultBot wires up the commands and middleware used by the webhook
+// Handler, plus media and store support when their environment variables
+// are set.
+func newDefaultBot() *Bot {
+	b := NewBot(os.Getenv(BOT_TOKEN_ENV))
+
+	b.Use(RecoverMiddleware, LoggingMiddleware)
+
+	b.Handle("/start", handleStart)
+	b.Handle("/settings", handleSettings)
+	b.Handle("/history", handleHistory)
+	b.Handle("/glossary", handleGlossary)
+
+	if endpoint := os.Getenv(WHISPER_ENDPOINT_ENV); endpoint != "" {
+		b.WithMedia(FFmpegTranscoder{}, &WhisperRecognizer{Endpoint: endpoint}, 0, 0)
+	}
+
+	if dir := os.Getenv(STORE_DIR_ENV); dir != "" {
+		s, err := store.OpenBadgerStore(dir)
+		if err != nil {
+			log.Printf("could not open store at %s: %s", dir, err.Error())
+		} else {
+			b.WithStore(s)
+		}
+	}
+
+	return b
+}
+
+// handleStart replies to the /start command. It is currently a no-op, kept
+// so Telegram's chat creation ping doesn't get run through the Finglish
+// converter.
+func handleStart(c *Context) error {
+	return nil
+}
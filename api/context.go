@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Context wraps a single incoming update and provides the helpers handlers
+// use to inspect and reply to it.
+type Context struct {
+	bot    *Bot
+	update update
+
+	// textOverride, when set, is returned by Text instead of the message's
+	// own text. The media and document handlers set it to the transcribed
+	// text or the document's contents before handing off to the text
+	// handler.
+	textOverride *string
+}
+
+// Update returns the update this Context wraps.
+func (c *Context) Update() update {
+	return c.update
+}
+
+// ChatID returns the id of the chat the update came from.
+func (c *Context) ChatID() int {
+	return c.update.Message.Chat.ID
+}
+
+// Text returns the text associated with the incoming update: the message's
+// own text, or, for voice/audio/document messages, the transcribed or
+// extracted text set by the media pipeline.
+func (c *Context) Text() string {
+	if c.textOverride != nil {
+		return *c.textOverride
+	}
+	return c.update.Message.Text
+}
+
+// Args returns the whitespace-separated words following a command, e.g. for
+// "/mode offline" Args returns ["offline"].
+func (c *Context) Args() []string {
+	fields := strings.Fields(c.update.Message.Text)
+	if len(fields) <= 1 {
+		return nil
+	}
+	return fields[1:]
+}
+
+// Send posts text to the chat the update came from, unconverted, using
+// the chat's preferred ParseMode if a store is configured. Text longer
+// than Telegram's sendMessage limit is uploaded as a .txt document
+// instead.
+func (c *Context) Send(text string) error {
+	opts := c.sendOptions()
+
+	if utf8.RuneCountInString(text) > telegramMaxMessageLength {
+		_, err := c.bot.client.SendDocument(c.ChatID(), "converted.txt", strings.NewReader(text), opts...)
+		return err
+	}
+
+	_, err := c.bot.client.SendMessage(c.ChatID(), text, opts...)
+	return err
+}
+
+// sendOptions builds the SendOptions derived from the chat's stored
+// preferences, if any.
+func (c *Context) sendOptions() []SendOption {
+	if c.bot.store == nil {
+		return nil
+	}
+
+	prefs, err := c.bot.store.GetPrefs(c.ChatID())
+	if err != nil || prefs.ParseMode == "" {
+		return nil
+	}
+
+	return []SendOption{ParseMode(prefs.ParseMode)}
+}
+
+// Reply is an alias for Send. It does not pass ReplyToMessageID, so the
+// reply is not threaded to the originating message; the update's message
+// id is not parsed.
+func (c *Context) Reply(text string) error {
+	return c.Send(text)
+}
@@ -7,19 +7,24 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
-	"strconv"
 	"strings"
+	"time"
+
+	"github.com/mehdieidi/dexxt/farsi"
+	"github.com/mehdieidi/dexxt/store"
 )
 
 const (
-	TELEGRAM_API_BASE_URL     = "https://api.telegram.org/bot"
-	TELEGRAM_API_SEND_MESSAGE = "/sendMessage"
-	BOT_TOKEN_ENV             = "TELEGRAM_BOT_TOKEN"
+	TELEGRAM_API_BASE_URL = "https://api.telegram.org/bot"
+	BOT_TOKEN_ENV         = "TELEGRAM_BOT_TOKEN"
+	ONLINE_MODE_ENV       = "DEXXT_ONLINE"
 )
 
-var telegramAPI string = TELEGRAM_API_BASE_URL + os.Getenv(BOT_TOKEN_ENV) + TELEGRAM_API_SEND_MESSAGE
+// onlineMode selects the remote Behnevis API for Finglish conversion
+// instead of the offline farsi package. Off by default so the bot works
+// fully offline and deterministically.
+var onlineMode = os.Getenv(ONLINE_MODE_ENV) != ""
 
 // update is a Telegram object that we receive every time a user interacts with the bot.
 type update struct {
@@ -81,7 +86,10 @@ func (c chat) String() string {
 	return fmt.Sprintf("(id: %d)", c.ID)
 }
 
-// Handler sends a message back to the chat.
+// Handler sends a message back to the chat. It is the entry point used when
+// the bot runs behind a webhook; see Bot.Start for the long-polling
+// alternative. Handler routes through defaultBot's command/middleware
+// chain; Bot.Start routes through the chain of the Bot it is called on.
 func Handler(w http.ResponseWriter, r *http.Request) {
 	// Parse incoming request
 	update, err := parseIncomingRequest(r)
@@ -90,12 +98,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	telegramResponseBody, err := sendToClient(update.Message.Chat.ID, strings.ToLower(update.Message.Text))
-	if err != nil {
-		log.Printf("got error %s from telegram, response body is %s", err.Error(), telegramResponseBody)
-	} else {
-		log.Printf("successfully distributed to chat id %d", update.Message.Chat.ID)
-	}
+	defaultBot.dispatch(*update)
 }
 
 // parseIncomingRequest parses incoming update to Update.
@@ -115,135 +118,61 @@ func parseIncomingRequest(r *http.Request) (*update, error) {
 	return &update, nil
 }
 
-// sendToClient sends a text message to the Telegram chat identified by the chat ID.
-func sendToClient(chatID int, incomingText string) (string, error) {
-	if incomingText == "/start" {
-		return "", nil
-	}
+// defaultTextHandler is the default HandlerFunc: it converts the incoming
+// Finglish text to Farsi and sends the result back. Conversion runs
+// through the offline farsi package, consulting the chat's glossary
+// overrides first if a store is configured, unless the chat's (or, absent
+// a store, the package-level) online preference is set, in which case the
+// remote Behnevis API is used instead. When a store is configured, the
+// conversion is also recorded to the chat's history.
+func defaultTextHandler(c *Context) error {
+	useOnline := onlineMode
+	var glossary map[string]string
+
+	if c.bot.store != nil {
+		prefs, err := c.bot.store.GetPrefs(c.ChatID())
+		if err != nil {
+			return err
+		}
+		useOnline = prefs.Online
 
-	text, err := getFarsiAPI(incomingText)
-	if err != nil {
-		return "", err
+		glossary, err = c.bot.store.GlossaryList(c.ChatID())
+		if err != nil {
+			return err
+		}
 	}
 
-	log.Printf("Sending %s to chat_id: %d", text, chatID)
-
-	response, err := http.PostForm(telegramAPI, url.Values{
-		"chat_id": {strconv.Itoa(chatID)},
-		"text":    {text},
-	})
-	if err != nil {
-		log.Printf("error when posting text to the chat: %s", err.Error())
-		return "", err
-	}
-	defer response.Body.Close()
+	var (
+		text string
+		err  error
+	)
 
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		log.Printf("error in parsing telegram answer %s", err.Error())
-		return "", err
+	if useOnline {
+		text, err = getFarsiAPI(strings.ToLower(c.Text()))
+		if err != nil {
+			return err
+		}
+	} else {
+		text = farsi.ConvertWithOverrides(c.Text(), glossary)
 	}
 
-	log.Printf("Body of Telegram Response: %s", string(body))
-
-	return string(body), nil
-}
-
-// getFarsi constructs and returns appropriate Farsi string associated with the given Finglish.
-func getFarsi(finglish string) string {
-	var farsi string
-
-	for i := 0; i < len(finglish); i++ {
-		switch finglish[i] {
-		case 'a':
-			farsi += "ا"
-		case 'b':
-			farsi += "ب"
-		case 'c':
-			if peekChar(i, finglish) == "h" {
-				farsi += "چ"
-				i++
-			} else {
-				farsi += "س"
-			}
-		case 'd':
-			farsi += "د"
-		case 'e':
-			continue
-		case 'f':
-			farsi += "ف"
-		case 'g':
-			if peekChar(i, finglish) == "h" {
-				farsi += "غ"
-				i++
-			} else {
-				farsi += "گ"
-			}
-		case 'h':
-			farsi += "ه"
-		case 'i':
-			farsi += "ی"
-		case 'j':
-			farsi += "ج"
-		case 'k':
-			if peekChar(i, finglish) == "h" {
-				farsi += "خ"
-				i++
-			} else {
-				farsi += "ک"
-			}
-		case 'l':
-			farsi += "ل"
-		case 'm':
-			farsi += "م"
-		case 'n':
-			farsi += "ن"
-		case 'o':
-			farsi += "و"
-		case 'p':
-			farsi += "پ"
-		case 'q':
-			farsi += "ک"
-		case 'r':
-			farsi += "ر"
-		case 's':
-			if peekChar(i, finglish) == "h" {
-				farsi += "ش"
-				i++
-			} else {
-				farsi += "س"
-			}
-		case 't':
-			farsi += "ت"
-		case 'u':
-			farsi += "و"
-		case 'v':
-			farsi += "و"
-		case 'w':
-			farsi += "و"
-		case 'x':
-			farsi += "خ"
-		case 'y':
-			farsi += "ی"
-		case 'z':
-			farsi += "ز"
-		default:
-			farsi += string(finglish[i])
+	if c.bot.store != nil {
+		if err := c.bot.store.AppendHistory(c.ChatID(), store.HistoryEntry{
+			Input:     c.Text(),
+			Output:    text,
+			Timestamp: time.Now(),
+		}); err != nil {
+			return err
 		}
 	}
 
-	return farsi
-}
-
-// peekChar returns the next char in the given string if exists.
-func peekChar(index int, str string) string {
-	if index+1 < len(str) {
-		return string(str[index+1])
-	}
-	return ""
+	return c.Send(text)
 }
 
-func getFarsiAPI(finglish string) (farsi string, err error) {
+// getFarsiAPI converts Finglish to Farsi via the remote Behnevis API. It is
+// only used when onlineMode is set; offline conversion uses the farsi
+// package instead.
+func getFarsiAPI(finglish string) (result string, err error) {
 	body := strings.NewReader(finglish)
 
 	req, err := http.NewRequest("POST", "https://9mkhzfaym3.execute-api.us-east-1.amazonaws.com/production/convert?", body)
@@ -277,16 +206,16 @@ func getFarsiAPI(finglish string) (farsi string, err error) {
 		return
 	}
 
-	var result map[string]string
+	var parts map[string]string
 
-	err = json.Unmarshal(resp_body, &result)
+	err = json.Unmarshal(resp_body, &parts)
 	if err != nil {
 		err = fmt.Errorf("err unMarshaling response body: %w", err)
 		return
 	}
 
-	for _, v := range result {
-		farsi += v
+	for _, v := range parts {
+		result += v
 	}
 
 	return
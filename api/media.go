@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"time"
+)
+
+const (
+	// defaultMaxFileSize mirrors the Telegram Bot API's own cap on files a
+	// bot can download via getFile.
+	defaultMaxFileSize = 20 << 20 // 20MB
+	// defaultMediaTimeout bounds the getFile lookup and download of a
+	// media file when Bot.WithMedia wasn't given an explicit timeout.
+	defaultMediaTimeout = 30 * time.Second
+	// chatConcurrency caps how many media jobs a single chat may have in
+	// flight at once.
+	chatConcurrency = 1
+)
+
+// Transcoder converts an audio stream from one format to another, e.g.
+// Telegram's OGG/Opus voice notes to the WAV most speech recognizers
+// expect.
+type Transcoder interface {
+	Transcode(r io.Reader) (io.Reader, error)
+}
+
+// FFmpegTranscoder shells out to the system ffmpeg binary to transcode to
+// 16kHz mono WAV.
+type FFmpegTranscoder struct{}
+
+// Transcode implements Transcoder.
+func (FFmpegTranscoder) Transcode(r io.Reader) (io.Reader, error) {
+	cmd := exec.Command("ffmpeg", "-i", "pipe:0", "-ar", "16000", "-ac", "1", "-f", "wav", "pipe:1")
+	cmd.Stdin = r
+
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg transcode failed: %w: %s", err, stderr.String())
+	}
+
+	return &out, nil
+}
+
+// SpeechRecognizer transcribes an audio stream to text.
+type SpeechRecognizer interface {
+	Recognize(r io.Reader) (string, error)
+}
+
+// WhisperRecognizer transcribes audio by posting it to a Whisper HTTP API
+// (e.g. whisper.cpp's server or openai/whisper-asr-webservice).
+type WhisperRecognizer struct {
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// Recognize implements SpeechRecognizer.
+func (w *WhisperRecognizer) Recognize(r io.Reader) (string, error) {
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("audio_file", "audio.wav")
+	if err != nil {
+		return "", fmt.Errorf("err creating form file: %w", err)
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return "", fmt.Errorf("err copying audio into request: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("err closing multipart writer: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.Endpoint, &body)
+	if err != nil {
+		return "", fmt.Errorf("err creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	client := w.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("err calling whisper endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("err reading whisper response: %w", err)
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("err unmarshaling whisper response: %w", err)
+	}
+
+	return result.Text, nil
+}
+
+// mediaHandler downloads the voice/audio file identified by fileID,
+// transcodes and transcribes it, then runs the resulting text through the
+// same pipeline as a typed message.
+func (b *Bot) mediaHandler(fileID string) HandlerFunc {
+	return func(c *Context) error {
+		if b.recognizer == nil {
+			return fmt.Errorf("voice messages aren't configured for this bot")
+		}
+
+		if b.store != nil {
+			prefs, err := b.store.GetPrefs(c.ChatID())
+			if err != nil {
+				return err
+			}
+			if !prefs.AutoReplyVoice {
+				return c.Send("voice auto-reply is off; turn it on with /settings autoreply on")
+			}
+		}
+
+		release, err := b.acquireChatSlot(c.ChatID())
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.timeoutOrDefault())
+		defer cancel()
+
+		raw, err := b.downloadFile(ctx, fileID)
+		if err != nil {
+			return err
+		}
+
+		transcoder := b.transcoder
+		if transcoder == nil {
+			transcoder = FFmpegTranscoder{}
+		}
+
+		wav, err := transcoder.Transcode(bytes.NewReader(raw))
+		if err != nil {
+			return err
+		}
+
+		text, err := b.recognizer.Recognize(wav)
+		if err != nil {
+			return err
+		}
+
+		c.textOverride = &text
+		return b.textHandler(c)
+	}
+}
+
+// documentHandler downloads a .txt document identified by fileID and runs
+// its contents through the same pipeline as a typed message.
+func (b *Bot) documentHandler(fileID string) HandlerFunc {
+	return func(c *Context) error {
+		release, err := b.acquireChatSlot(c.ChatID())
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.timeoutOrDefault())
+		defer cancel()
+
+		content, err := b.downloadFile(ctx, fileID)
+		if err != nil {
+			return err
+		}
+
+		text := string(content)
+		c.textOverride = &text
+		return b.textHandler(c)
+	}
+}
+
+// timeoutOrDefault returns the configured media timeout, or
+// defaultMediaTimeout if none was set via WithMedia.
+func (b *Bot) timeoutOrDefault() time.Duration {
+	if b.mediaTimeout > 0 {
+		return b.mediaTimeout
+	}
+	return defaultMediaTimeout
+}
+
+// acquireChatSlot reserves one of a chat's media job slots, returning a
+// release func to call when the job is done. It errors instead of
+// blocking so a chat can't queue up unbounded concurrent media jobs.
+func (b *Bot) acquireChatSlot(chatID int) (release func(), err error) {
+	b.chatSlotsMu.Lock()
+	if b.chatSlots == nil {
+		b.chatSlots = make(map[int]chan struct{})
+	}
+	slot, ok := b.chatSlots[chatID]
+	if !ok {
+		slot = make(chan struct{}, chatConcurrency)
+		b.chatSlots[chatID] = slot
+	}
+	b.chatSlotsMu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	default:
+		return nil, fmt.Errorf("chat %d already has a media job in progress", chatID)
+	}
+}
+
+// telegramFileResponse is the shape of a Telegram getFile response.
+type telegramFileResponse struct {
+	OK     bool `json:"ok"`
+	Result struct {
+		FileSize int64  `json:"file_size"`
+		FilePath string `json:"file_path"`
+	} `json:"result"`
+}
+
+// downloadFile resolves fileID to a file_path via getFile, then downloads
+// it, rejecting anything over the bot's configured max file size.
+func (b *Bot) downloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	maxSize := b.maxFileSize
+	if maxSize == 0 {
+		maxSize = defaultMaxFileSize
+	}
+
+	getFileURL := TELEGRAM_API_BASE_URL + b.token + "/getFile?file_id=" + url.QueryEscape(fileID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, getFileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("err creating getFile request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("err calling getFile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("err reading getFile response: %w", err)
+	}
+
+	var tf telegramFileResponse
+	if err := json.Unmarshal(body, &tf); err != nil {
+		return nil, fmt.Errorf("err unmarshaling getFile response: %w", err)
+	}
+	if !tf.OK {
+		return nil, fmt.Errorf("getFile returned not ok: %s", string(body))
+	}
+	if tf.Result.FileSize > maxSize {
+		return nil, fmt.Errorf("file size %d exceeds max allowed size %d", tf.Result.FileSize, maxSize)
+	}
+
+	fileURL := fmt.Sprintf("https://api.telegram.org/file/bot%s/%s", b.token, tf.Result.FilePath)
+
+	fileReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("err creating file download request: %w", err)
+	}
+
+	fileResp, err := http.DefaultClient.Do(fileReq)
+	if err != nil {
+		return nil, fmt.Errorf("err downloading file: %w", err)
+	}
+	defer fileResp.Body.Close()
+
+	return io.ReadAll(io.LimitReader(fileResp.Body, maxSize))
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+)
+
+// RecoverMiddleware recovers a panicking handler, including the ffmpeg
+// exec/Whisper media path, so one bad update can't bring down the whole
+// process when running as a long-polling daemon via Bot.Start.
+func RecoverMiddleware(next HandlerFunc) HandlerFunc {
+	return func(c *Context) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("recovered from panic: %v", r)
+			}
+		}()
+
+		return next(c)
+	}
+}
+
+// LoggingMiddleware logs every update dispatch and the error, if any, its
+// handler returned.
+func LoggingMiddleware(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		err := next(c)
+		if err != nil {
+			log.Printf("chat %d: handler error: %s", c.ChatID(), err.Error())
+		} else {
+			log.Printf("chat %d: handled update", c.ChatID())
+		}
+		return err
+	}
+}
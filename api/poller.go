@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// pollErrorBackoff is how long Poll waits before retrying a failed
+// getUpdates call, so a sustained outage doesn't spin the CPU or hammer
+// Telegram with back-to-back requests.
+const pollErrorBackoff = 2 * time.Second
+
+// Poller fetches updates from some source and feeds them onto dest until
+// stop is closed, at which point it must close dest and return.
+type Poller interface {
+	Poll(b *Bot, dest chan update, stop chan struct{})
+}
+
+// LongPoller retrieves updates via repeated calls to Telegram's getUpdates
+// endpoint, as an alternative to running behind a webhook.
+type LongPoller struct {
+	// Timeout is the long-polling timeout, in seconds, passed to getUpdates.
+	Timeout int
+	// Limit caps the number of updates returned per getUpdates call. Zero
+	// leaves it up to Telegram's default.
+	Limit int
+	// AllowedUpdates restricts which update types are delivered; nil means
+	// the Telegram default.
+	AllowedUpdates []string
+}
+
+// getUpdatesResponse is the shape of a Telegram getUpdates response.
+type getUpdatesResponse struct {
+	OK     bool     `json:"ok"`
+	Result []update `json:"result"`
+}
+
+// Poll implements Poller by calling getUpdates in a loop, advancing the
+// offset past the last update id seen so Telegram doesn't redeliver it.
+func (lp *LongPoller) Poll(b *Bot, dest chan update, stop chan struct{}) {
+	var offset int
+
+	for {
+		select {
+		case <-stop:
+			close(dest)
+			return
+		default:
+		}
+
+		updates, err := lp.getUpdates(b.token, offset)
+		if err != nil {
+			log.Printf("long poller: error fetching updates: %s", err.Error())
+
+			select {
+			case <-stop:
+				close(dest)
+				return
+			case <-time.After(pollErrorBackoff):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateId + 1
+			dest <- u
+		}
+	}
+}
+
+// getUpdates performs a single getUpdates call.
+func (lp *LongPoller) getUpdates(token string, offset int) ([]update, error) {
+	values := url.Values{
+		"offset":  {strconv.Itoa(offset)},
+		"timeout": {strconv.Itoa(lp.Timeout)},
+	}
+	if lp.Limit > 0 {
+		values.Set("limit", strconv.Itoa(lp.Limit))
+	}
+	if len(lp.AllowedUpdates) > 0 {
+		allowed, err := json.Marshal(lp.AllowedUpdates)
+		if err != nil {
+			return nil, fmt.Errorf("err marshaling allowed updates: %w", err)
+		}
+		values.Set("allowed_updates", string(allowed))
+	}
+
+	resp, err := http.Get(TELEGRAM_API_BASE_URL + token + "/getUpdates?" + values.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("err calling getUpdates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("err reading getUpdates response: %w", err)
+	}
+
+	var result getUpdatesResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("err unmarshaling getUpdates response: %w", err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("getUpdates returned not ok: %s", string(body))
+	}
+
+	return result.Result, nil
+}
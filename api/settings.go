@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// handleSettings implements /settings [online|offline|autoreply on|off|parsemode markdown|html|off].
+// With no arguments it reports the chat's current preferences.
+func handleSettings(c *Context) error {
+	if c.bot.store == nil {
+		return c.Send("settings aren't available: no store configured")
+	}
+
+	prefs, err := c.bot.store.GetPrefs(c.ChatID())
+	if err != nil {
+		return err
+	}
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(fmt.Sprintf(
+			"mode: %s\nauto-reply to voice: %t\nparse mode: %s",
+			onOrOffline(prefs.Online), prefs.AutoReplyVoice, orOff(prefs.ParseMode),
+		))
+	}
+
+	switch args[0] {
+	case "online":
+		prefs.Online = true
+	case "offline":
+		prefs.Online = false
+
+	case "autoreply":
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			return c.Send("usage: /settings autoreply on|off")
+		}
+		prefs.AutoReplyVoice = args[1] == "on"
+
+	case "parsemode":
+		if len(args) < 2 {
+			return c.Send("usage: /settings parsemode markdown|html|off")
+		}
+		switch args[1] {
+		case "markdown":
+			prefs.ParseMode = ParseModeMarkdownV2
+		case "html":
+			prefs.ParseMode = ParseModeHTML
+		case "off":
+			prefs.ParseMode = ""
+		default:
+			return c.Send("usage: /settings parsemode markdown|html|off")
+		}
+
+	default:
+		return c.Send("usage: /settings [online|offline|autoreply on|off|parsemode markdown|html|off]")
+	}
+
+	if err := c.bot.store.SetPrefs(c.ChatID(), prefs); err != nil {
+		return err
+	}
+
+	return c.Send("settings updated")
+}
+
+// handleHistory implements /history, replying with the chat's 10 most
+// recent conversions, most recent first.
+func handleHistory(c *Context) error {
+	if c.bot.store == nil {
+		return c.Send("history isn't available: no store configured")
+	}
+
+	entries, err := c.bot.store.RecentHistory(c.ChatID(), 10)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return c.Send("no conversion history yet")
+	}
+
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%s -> %s\n", entry.Input, entry.Output)
+	}
+
+	return c.Send(strings.TrimRight(b.String(), "\n"))
+}
+
+// handleGlossary implements /glossary add <token> <farsi>|del <token>|list.
+// Glossary overrides are consulted by the offline converter ahead of its
+// default rules.
+func handleGlossary(c *Context) error {
+	if c.bot.store == nil {
+		return c.Send("glossary isn't available: no store configured")
+	}
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send("usage: /glossary add <token> <farsi>|del <token>|list")
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			return c.Send("usage: /glossary add <token> <farsi>")
+		}
+		token := strings.ToLower(args[1])
+		farsiText := strings.Join(args[2:], " ")
+		if err := c.bot.store.GlossarySet(c.ChatID(), token, farsiText); err != nil {
+			return err
+		}
+		return c.Send(fmt.Sprintf("added %s -> %s", token, farsiText))
+
+	case "del":
+		if len(args) < 2 {
+			return c.Send("usage: /glossary del <token>")
+		}
+		token := strings.ToLower(args[1])
+		if err := c.bot.store.GlossaryDelete(c.ChatID(), token); err != nil {
+			return err
+		}
+		return c.Send(fmt.Sprintf("removed %s", token))
+
+	case "list":
+		glossary, err := c.bot.store.GlossaryList(c.ChatID())
+		if err != nil {
+			return err
+		}
+		if len(glossary) == 0 {
+			return c.Send("glossary is empty")
+		}
+		var b strings.Builder
+		for token, farsiText := range glossary {
+			fmt.Fprintf(&b, "%s -> %s\n", token, farsiText)
+		}
+		return c.Send(strings.TrimRight(b.String(), "\n"))
+
+	default:
+		return c.Send("usage: /glossary add <token> <farsi>|del <token>|list")
+	}
+}
+
+func onOrOffline(online bool) string {
+	if online {
+		return "online"
+	}
+	return "offline"
+}
+
+func orOff(parseMode string) string {
+	if parseMode == "" {
+		return "off"
+	}
+	return parseMode
+}
@@ -0,0 +1,243 @@
+// Package farsi transliterates Finglish (Farsi written with the Latin
+// alphabet) to Farsi script, entirely offline and deterministically.
+//
+// Matching is greedy and longest-prefix-wins: at every position the
+// longest rule key (up to 3 characters) that matches the remaining input
+// is applied before falling back to single-character rules. A handful of
+// positional rules then cover cases a flat table can't: word-initial
+// vowels get a leading carrier letter, medial short vowels are usually
+// silent in Farsi orthography and are dropped, and word-final "e" is
+// written out.
+package farsi
+
+import (
+	"strings"
+	"sync"
+)
+
+// digraphs are matched greedily, longest key first, before any
+// single-character rule. Keys are listed longest first purely for
+// readability; matching itself checks length explicitly.
+var digraphs = []struct {
+	key   string
+	farsi string
+}{
+	{"kh", "خ"},
+	{"gh", "غ"},
+	{"ch", "چ"},
+	{"sh", "ش"},
+	{"zh", "ژ"},
+	{"oo", "و"},
+	{"ee", "ی"},
+	{"ou", "و"},
+	{"aa", "ا"},
+}
+
+// consonants holds the default single-character mapping for every letter
+// that isn't a vowel or already covered by a digraph rule above.
+var consonants = map[byte]string{
+	'b': "ب",
+	'c': "س",
+	'd': "د",
+	'f': "ف",
+	'g': "گ",
+	'h': "ه",
+	'j': "ج",
+	'k': "ک",
+	'l': "ل",
+	'm': "م",
+	'n': "ن",
+	'p': "پ",
+	'q': "ک",
+	'r': "ر",
+	's': "س",
+	't': "ت",
+	'x': "خ",
+	'y': "ی",
+	'z': "ز",
+}
+
+// initialVowels is the carrier letter a vowel takes when it opens a word.
+var initialVowels = map[byte]string{
+	'a': "ا",
+	'e': "ا",
+	'i': "ای",
+	'o': "او",
+	'u': "او",
+}
+
+// medialDiacritics is what a, e and o become in a short (<=2 letter)
+// token instead of being dropped outright.
+var medialDiacritics = map[byte]string{
+	'a': "َ", // fatha
+	'e': "ِ", // kasra
+	'o': "ُ", // damma
+}
+
+// overrideRule is a caller-registered rule key, as added via AddRule.
+type overrideRule struct {
+	key   string
+	farsi string
+}
+
+// ruleOverrides lets callers add or replace digraph/trigraph rules ahead
+// of the built-in table, e.g. for dialect-specific spellings. It's guarded
+// by ruleOverridesMu since AddRule may run concurrently with Convert and
+// ConvertWithOverrides, e.g. across webhook goroutines.
+var (
+	ruleOverridesMu sync.RWMutex
+	ruleOverrides   []overrideRule
+)
+
+// AddRule registers a rule key (1 to 3 ASCII characters) that takes
+// precedence over the built-in table when it's the longest match at a
+// given position, matched the same greedy, longest-prefix-wins way.
+func AddRule(key, farsi string) {
+	ruleOverridesMu.Lock()
+	defer ruleOverridesMu.Unlock()
+	ruleOverrides = append(ruleOverrides, overrideRule{key, farsi})
+}
+
+func isVowel(b byte) bool {
+	switch b {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	}
+	return false
+}
+
+// Convert transliterates Finglish text to Farsi script. Words are split on
+// whitespace and punctuation, converted independently, and the original
+// separators are preserved in the output.
+func Convert(text string) string {
+	return convert(text, nil)
+}
+
+// ConvertWithOverrides is like Convert, but looks up each word in
+// overrides (case-insensitively) before falling back to the rule-based
+// conversion, letting a caller's personal glossary win over the default
+// rules.
+func ConvertWithOverrides(text string, overrides map[string]string) string {
+	return convert(text, overrides)
+}
+
+func convert(text string, overrides map[string]string) string {
+	var out strings.Builder
+
+	word := make([]byte, 0, 16)
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := string(word)
+		if farsi, ok := overrides[strings.ToLower(w)]; ok {
+			out.WriteString(farsi)
+		} else {
+			out.WriteString(convertWord(w))
+		}
+		word = word[:0]
+	}
+
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		if isWordByte(b) {
+			word = append(word, b)
+			continue
+		}
+		flush()
+		out.WriteByte(b)
+	}
+	flush()
+
+	return out.String()
+}
+
+// isWordByte reports whether b can be part of a Finglish token.
+func isWordByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// convertWord transliterates a single whitespace/punctuation-delimited
+// token.
+func convertWord(word string) string {
+	word = strings.ToLower(word)
+
+	var out strings.Builder
+
+	for i := 0; i < len(word); {
+		if key, farsi, n := matchRule(word[i:]); n > 0 {
+			if key == "aa" && i == 0 {
+				farsi = "آ"
+			}
+			out.WriteString(farsi)
+			i += n
+			continue
+		}
+
+		b := word[i]
+
+		// w and v both transliterate to "و" in isolation, but a w right
+		// after a vowel is treated as the offglide of a diphthong (e.g.
+		// "aw", "brow") and writes nothing.
+		if b == 'w' && i > 0 && isVowel(word[i-1]) {
+			i++
+			continue
+		}
+		if b == 'v' || b == 'w' {
+			out.WriteString("و")
+			i++
+			continue
+		}
+
+		if isVowel(b) {
+			switch {
+			case i == 0:
+				out.WriteString(initialVowels[b])
+			case b == 'e' && i == len(word)-1:
+				out.WriteString("ه")
+			case b == 'i':
+				out.WriteString("ی")
+			case b == 'u':
+				out.WriteString("و")
+			case len(word) <= 2:
+				out.WriteString(medialDiacritics[b])
+			default:
+				// Medial short vowel: dropped, as in standard Farsi spelling.
+			}
+			i++
+			continue
+		}
+
+		if farsi, ok := consonants[b]; ok {
+			out.WriteString(farsi)
+		} else {
+			out.WriteByte(b)
+		}
+		i++
+	}
+
+	return out.String()
+}
+
+// matchRule returns the longest override or digraph rule matching the
+// start of s, and how many bytes it consumed. Overrides and the built-in
+// table compete on equal footing: the longest key wins regardless of
+// which table it came from, so a short override can never pre-empt a
+// longer built-in digraph.
+func matchRule(s string) (key, farsi string, n int) {
+	ruleOverridesMu.RLock()
+	for _, r := range ruleOverrides {
+		if len(r.key) > n && len(r.key) <= len(s) && s[:len(r.key)] == r.key {
+			key, farsi, n = r.key, r.farsi, len(r.key)
+		}
+	}
+	ruleOverridesMu.RUnlock()
+
+	for _, d := range digraphs {
+		if len(d.key) > n && len(d.key) <= len(s) && s[:len(d.key)] == d.key {
+			key, farsi, n = d.key, d.farsi, len(d.key)
+		}
+	}
+
+	return
+}
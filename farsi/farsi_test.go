@@ -0,0 +1,65 @@
+package farsi
+
+import "testing"
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"digraph", "kh", "خ"},
+		{"word-initial aa gets the alef-madda form", "aa", "آ"},
+		{"medial aa is a plain alef", "baad", "باد"},
+		{"word-initial vowel gets a carrier letter", "ali", "الی"},
+		{"word-final e is written out", "name", "نمه"},
+		{"medial short vowel is dropped", "salam", "سلم"},
+		{"short token gets a diacritic instead of being dropped", "bo", "بُ"},
+		{"v always maps to vav", "vali", "ولی"},
+		{"w not preceded by a vowel behaves like v", "wali", "ولی"},
+		{"w preceded by a vowel is a silent offglide", "aw", "ا"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Convert(tt.in); got != tt.want {
+				t.Errorf("Convert(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithOverrides(t *testing.T) {
+	overrides := map[string]string{"ok": "باشه"}
+
+	got := ConvertWithOverrides("ok salam", overrides)
+	want := "باشه سلم"
+	if got != want {
+		t.Errorf("ConvertWithOverrides(%q) = %q, want %q", "ok salam", got, want)
+	}
+}
+
+func TestAddRuleLongestPrefixWins(t *testing.T) {
+	// A shorter override must not pre-empt a longer built-in digraph match.
+	AddRule("k", "ک")
+	defer resetRuleOverrides()
+
+	if got, want := Convert("kh"), "خ"; got != want {
+		t.Errorf("Convert(%q) = %q, want %q (built-in kh digraph should still win)", "kh", got, want)
+	}
+}
+
+func TestAddRuleWinsOverEqualLengthBuiltin(t *testing.T) {
+	AddRule("sh", "ش‌ش")
+	defer resetRuleOverrides()
+
+	if got, want := Convert("sh"), "ش‌ش"; got != want {
+		t.Errorf("Convert(%q) = %q, want %q (override should win a same-length tie)", "sh", got, want)
+	}
+}
+
+func resetRuleOverrides() {
+	ruleOverridesMu.Lock()
+	defer ruleOverridesMu.Unlock()
+	ruleOverrides = nil
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+// maxHistoryPerChat caps how many history entries BadgerStore keeps per
+// chat so a chat's record doesn't grow unbounded.
+const maxHistoryPerChat = 100
+
+// chatRecord is the JSON blob BadgerStore keeps at one key per chat.
+type chatRecord struct {
+	Prefs    Prefs             `json:"prefs"`
+	History  []HistoryEntry    `json:"history"`
+	Glossary map[string]string `json:"glossary"`
+}
+
+// BadgerStore is a Store backed by BadgerDB, keyed by chat ID.
+type BadgerStore struct {
+	db *badger.DB
+
+	// chatLocksMu guards chatLocks itself; chatLocks serializes the
+	// read-modify-write cycle on a single chat's record so two concurrent
+	// updates (e.g. a glossary edit racing a settings change) can't
+	// interleave and silently drop one of them.
+	chatLocksMu sync.Mutex
+	chatLocks   map[int]*sync.Mutex
+}
+
+// OpenBadgerStore opens (creating if necessary) a BadgerDB at dir.
+func OpenBadgerStore(dir string) (*BadgerStore, error) {
+	db, err := badger.Open(badger.DefaultOptions(dir))
+	if err != nil {
+		return nil, fmt.Errorf("err opening badger db at %s: %w", dir, err)
+	}
+	return &BadgerStore{db: db, chatLocks: make(map[int]*sync.Mutex)}, nil
+}
+
+// chatLock returns the mutex serializing read-modify-write updates of
+// chatID's record, creating it on first use. Plain reads don't take it.
+func (s *BadgerStore) chatLock(chatID int) *sync.Mutex {
+	s.chatLocksMu.Lock()
+	defer s.chatLocksMu.Unlock()
+
+	lock, ok := s.chatLocks[chatID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.chatLocks[chatID] = lock
+	}
+	return lock
+}
+
+// updateRecord serializes a read-modify-write cycle on chatID's record
+// via chatLock, applying fn to the record read from Badger before writing
+// it back.
+func (s *BadgerStore) updateRecord(chatID int, fn func(record *chatRecord)) error {
+	lock := s.chatLock(chatID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	record, err := s.readRecord(chatID)
+	if err != nil {
+		return err
+	}
+
+	fn(&record)
+
+	return s.writeRecord(chatID, record)
+}
+
+// Close closes the underlying BadgerDB.
+func (s *BadgerStore) Close() error {
+	return s.db.Close()
+}
+
+func chatKey(chatID int) []byte {
+	return []byte("chat:" + strconv.Itoa(chatID))
+}
+
+func (s *BadgerStore) readRecord(chatID int) (chatRecord, error) {
+	record := chatRecord{Glossary: make(map[string]string)}
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(chatKey(chatID))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &record)
+		})
+	})
+	if err != nil {
+		return chatRecord{}, fmt.Errorf("err reading chat %d record: %w", chatID, err)
+	}
+	if record.Glossary == nil {
+		record.Glossary = make(map[string]string)
+	}
+
+	return record, nil
+}
+
+func (s *BadgerStore) writeRecord(chatID int, record chatRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("err marshaling chat %d record: %w", chatID, err)
+	}
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(chatKey(chatID), value)
+	})
+}
+
+// GetPrefs implements Store.
+func (s *BadgerStore) GetPrefs(chatID int) (Prefs, error) {
+	record, err := s.readRecord(chatID)
+	if err != nil {
+		return Prefs{}, err
+	}
+	return record.Prefs, nil
+}
+
+// SetPrefs implements Store.
+func (s *BadgerStore) SetPrefs(chatID int, prefs Prefs) error {
+	return s.updateRecord(chatID, func(record *chatRecord) {
+		record.Prefs = prefs
+	})
+}
+
+// AppendHistory implements Store.
+func (s *BadgerStore) AppendHistory(chatID int, entry HistoryEntry) error {
+	return s.updateRecord(chatID, func(record *chatRecord) {
+		record.History = append(record.History, entry)
+		if len(record.History) > maxHistoryPerChat {
+			record.History = record.History[len(record.History)-maxHistoryPerChat:]
+		}
+	})
+}
+
+// RecentHistory implements Store.
+func (s *BadgerStore) RecentHistory(chatID int, limit int) ([]HistoryEntry, error) {
+	record, err := s.readRecord(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := len(record.History) - limit
+	if start < 0 {
+		start = 0
+	}
+
+	recent := make([]HistoryEntry, 0, len(record.History)-start)
+	for i := len(record.History) - 1; i >= start; i-- {
+		recent = append(recent, record.History[i])
+	}
+	return recent, nil
+}
+
+// GlossarySet implements Store.
+func (s *BadgerStore) GlossarySet(chatID int, token, farsi string) error {
+	return s.updateRecord(chatID, func(record *chatRecord) {
+		record.Glossary[token] = farsi
+	})
+}
+
+// GlossaryDelete implements Store.
+func (s *BadgerStore) GlossaryDelete(chatID int, token string) error {
+	return s.updateRecord(chatID, func(record *chatRecord) {
+		delete(record.Glossary, token)
+	})
+}
+
+// GlossaryList implements Store.
+func (s *BadgerStore) GlossaryList(chatID int) (map[string]string, error) {
+	record, err := s.readRecord(chatID)
+	if err != nil {
+		return nil, err
+	}
+	return record.Glossary, nil
+}
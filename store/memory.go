@@ -0,0 +1,108 @@
+package store
+
+import "sync"
+
+// chatState is one chat's data in a MemoryStore.
+type chatState struct {
+	prefs    Prefs
+	history  []HistoryEntry
+	glossary map[string]string
+}
+
+// MemoryStore is an in-memory Store, suitable for tests or a bot that
+// doesn't need persistence across restarts.
+type MemoryStore struct {
+	mu    sync.Mutex
+	chats map[int]*chatState
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{chats: make(map[int]*chatState)}
+}
+
+func (s *MemoryStore) chat(chatID int) *chatState {
+	c, ok := s.chats[chatID]
+	if !ok {
+		c = &chatState{glossary: make(map[string]string)}
+		s.chats[chatID] = c
+	}
+	return c
+}
+
+// GetPrefs implements Store.
+func (s *MemoryStore) GetPrefs(chatID int) (Prefs, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.chat(chatID).prefs, nil
+}
+
+// SetPrefs implements Store.
+func (s *MemoryStore) SetPrefs(chatID int, prefs Prefs) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chat(chatID).prefs = prefs
+	return nil
+}
+
+// AppendHistory implements Store.
+func (s *MemoryStore) AppendHistory(chatID int, entry HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.chat(chatID)
+	c.history = append(c.history, entry)
+	return nil
+}
+
+// RecentHistory implements Store.
+func (s *MemoryStore) RecentHistory(chatID int, limit int) ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.chat(chatID).history
+
+	start := len(history) - limit
+	if start < 0 {
+		start = 0
+	}
+
+	recent := make([]HistoryEntry, 0, len(history)-start)
+	for i := len(history) - 1; i >= start; i-- {
+		recent = append(recent, history[i])
+	}
+	return recent, nil
+}
+
+// GlossarySet implements Store.
+func (s *MemoryStore) GlossarySet(chatID int, token, farsi string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chat(chatID).glossary[token] = farsi
+	return nil
+}
+
+// GlossaryDelete implements Store.
+func (s *MemoryStore) GlossaryDelete(chatID int, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.chat(chatID).glossary, token)
+	return nil
+}
+
+// GlossaryList implements Store.
+func (s *MemoryStore) GlossaryList(chatID int) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	glossary := s.chat(chatID).glossary
+	out := make(map[string]string, len(glossary))
+	for k, v := range glossary {
+		out[k] = v
+	}
+	return out, nil
+}
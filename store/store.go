@@ -0,0 +1,50 @@
+// Package store persists per-chat preferences, conversion history and
+// glossary overrides so the bot remembers them across invocations.
+package store
+
+import "time"
+
+// Prefs holds a chat's configurable behavior.
+type Prefs struct {
+	// Online selects the remote Behnevis API for conversion instead of the
+	// offline farsi package.
+	Online bool
+	// AutoReplyVoice makes the bot transcribe and convert voice messages
+	// without being asked.
+	AutoReplyVoice bool
+	// ParseMode is the Telegram parse mode used for replies, e.g.
+	// "MarkdownV2" or "HTML". Empty means plain text.
+	ParseMode string
+}
+
+// HistoryEntry records one conversion performed for a chat.
+type HistoryEntry struct {
+	Input     string
+	Output    string
+	Timestamp time.Time
+}
+
+// Store is implemented by the bot's persistence layer. MemoryStore and
+// BadgerStore are the two implementations; MemoryStore is for tests, and
+// BadgerStore is for production use.
+type Store interface {
+	// GetPrefs returns chatID's preferences, or the zero value if none
+	// have been set yet.
+	GetPrefs(chatID int) (Prefs, error)
+	// SetPrefs replaces chatID's preferences.
+	SetPrefs(chatID int, prefs Prefs) error
+
+	// AppendHistory records a conversion for chatID.
+	AppendHistory(chatID int, entry HistoryEntry) error
+	// RecentHistory returns chatID's most recent conversions, most recent
+	// first, capped at limit entries.
+	RecentHistory(chatID int, limit int) ([]HistoryEntry, error)
+
+	// GlossarySet adds or replaces a glossary override mapping token to
+	// farsi for chatID.
+	GlossarySet(chatID int, token, farsi string) error
+	// GlossaryDelete removes chatID's override for token, if any.
+	GlossaryDelete(chatID int, token string) error
+	// GlossaryList returns all of chatID's glossary overrides.
+	GlossaryList(chatID int) (map[string]string, error)
+}
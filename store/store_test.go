@@ -0,0 +1,100 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStorePrefsRoundTrip(t *testing.T) {
+	s := NewMemoryStore()
+
+	prefs, err := s.GetPrefs(1)
+	if err != nil {
+		t.Fatalf("GetPrefs on unseen chat: %s", err)
+	}
+	if prefs != (Prefs{}) {
+		t.Fatalf("GetPrefs on unseen chat = %+v, want zero value", prefs)
+	}
+
+	want := Prefs{Online: true, AutoReplyVoice: true, ParseMode: "MarkdownV2"}
+	if err := s.SetPrefs(1, want); err != nil {
+		t.Fatalf("SetPrefs: %s", err)
+	}
+
+	got, err := s.GetPrefs(1)
+	if err != nil {
+		t.Fatalf("GetPrefs: %s", err)
+	}
+	if got != want {
+		t.Fatalf("GetPrefs = %+v, want %+v", got, want)
+	}
+}
+
+func TestMemoryStoreHistoryOrderAndLimit(t *testing.T) {
+	s := NewMemoryStore()
+
+	for i := 0; i < 3; i++ {
+		entry := HistoryEntry{Input: string(rune('a' + i)), Output: string(rune('A' + i)), Timestamp: time.Unix(int64(i), 0)}
+		if err := s.AppendHistory(1, entry); err != nil {
+			t.Fatalf("AppendHistory: %s", err)
+		}
+	}
+
+	recent, err := s.RecentHistory(1, 2)
+	if err != nil {
+		t.Fatalf("RecentHistory: %s", err)
+	}
+	if len(recent) != 2 {
+		t.Fatalf("len(recent) = %d, want 2", len(recent))
+	}
+	if recent[0].Input != "c" || recent[1].Input != "b" {
+		t.Fatalf("recent = %+v, want most-recent-first [c, b]", recent)
+	}
+
+	all, err := s.RecentHistory(1, 10)
+	if err != nil {
+		t.Fatalf("RecentHistory: %s", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("len(all) = %d, want 3", len(all))
+	}
+}
+
+func TestMemoryStoreGlossary(t *testing.T) {
+	s := NewMemoryStore()
+
+	if err := s.GlossarySet(1, "salam", "سلام"); err != nil {
+		t.Fatalf("GlossarySet: %s", err)
+	}
+	if err := s.GlossarySet(1, "khodahafez", "خداحافظ"); err != nil {
+		t.Fatalf("GlossarySet: %s", err)
+	}
+
+	glossary, err := s.GlossaryList(1)
+	if err != nil {
+		t.Fatalf("GlossaryList: %s", err)
+	}
+	if len(glossary) != 2 || glossary["salam"] != "سلام" || glossary["khodahafez"] != "خداحافظ" {
+		t.Fatalf("GlossaryList = %+v, want both entries", glossary)
+	}
+
+	if err := s.GlossaryDelete(1, "salam"); err != nil {
+		t.Fatalf("GlossaryDelete: %s", err)
+	}
+
+	glossary, err = s.GlossaryList(1)
+	if err != nil {
+		t.Fatalf("GlossaryList: %s", err)
+	}
+	if _, ok := glossary["salam"]; ok {
+		t.Fatalf("GlossaryList still has deleted token: %+v", glossary)
+	}
+
+	other, err := s.GlossaryList(2)
+	if err != nil {
+		t.Fatalf("GlossaryList: %s", err)
+	}
+	if len(other) != 0 {
+		t.Fatalf("GlossaryList for unrelated chat = %+v, want empty", other)
+	}
+}